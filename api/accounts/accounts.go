@@ -0,0 +1,195 @@
+// Package accounts handles player registration, login/logout and the auth
+// middleware every other package's protected handlers wrap with.
+package accounts
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/pablobfonseca/canastracounter-go/api"
+)
+
+const sessionTTL = 24 * time.Hour
+
+// Init registers the accounts package's routes on mux.
+func Init(mux *http.ServeMux, database *sql.DB) {
+	mux.HandleFunc("/account/register", api.Wrap(http.StatusCreated, handleRegister(database)))
+	mux.HandleFunc("/account/login", api.Wrap(http.StatusOK, handleLogin(database)))
+	mux.HandleFunc("/account/logout", api.Wrap(http.StatusOK, handleLogout(database)))
+}
+
+// Middleware requires a valid bearer session token on the request and, on
+// success, injects the resolved player id into the request context before
+// calling next.
+func Middleware(database *sql.DB) func(api.HandlerFunc) api.HandlerFunc {
+	return func(next api.HandlerFunc) api.HandlerFunc {
+		return func(r *http.Request) (any, error) {
+			token, ok := bearerToken(r)
+			if !ok {
+				return nil, api.Status(http.StatusUnauthorized, errors.New("missing_token"))
+			}
+
+			var playerId int
+			var expiresAt time.Time
+			err := database.QueryRow("SELECT player_id, expires_at FROM sessions WHERE token = ?", token).Scan(&playerId, &expiresAt)
+			if err == sql.ErrNoRows {
+				return nil, api.Status(http.StatusUnauthorized, errors.New("invalid_token"))
+			}
+			if err != nil {
+				return nil, err
+			}
+			if time.Now().After(expiresAt) {
+				return nil, api.Status(http.StatusUnauthorized, errors.New("token_expired"))
+			}
+
+			return next(api.WithPlayerID(r, playerId))
+		}
+	}
+}
+
+func handleRegister(database *sql.DB) api.HandlerFunc {
+	return func(r *http.Request) (any, error) {
+		if r.Method != http.MethodPost {
+			return nil, api.Status(http.StatusMethodNotAllowed, errors.New("method_not_allowed"))
+		}
+
+		var requestData struct {
+			Name     string `json:"name"`
+			Password string `json:"password"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
+			return nil, api.Status(http.StatusBadRequest, errors.New("invalid_json"))
+		}
+
+		if requestData.Name == "" {
+			return nil, api.Status(http.StatusBadRequest, errors.New("validation_error: name can't be blank"))
+		}
+		if requestData.Password == "" {
+			return nil, api.Status(http.StatusBadRequest, errors.New("validation_error: password can't be blank"))
+		}
+
+		passwordHash, err := bcrypt.GenerateFromPassword([]byte(requestData.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := database.Exec("INSERT INTO players (name, password_hash) VALUES (?, ?)", requestData.Name, string(passwordHash))
+		if isUniqueConstraintErr(err) {
+			return nil, api.Status(http.StatusConflict, errors.New("name_already_taken"))
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+
+		return struct {
+			UserId  int64  `json:"id"`
+			Message string `json:"message"`
+			Success bool   `json:"success"`
+		}{id, "user_created", true}, nil
+	}
+}
+
+func handleLogin(database *sql.DB) api.HandlerFunc {
+	return func(r *http.Request) (any, error) {
+		if r.Method != http.MethodPost {
+			return nil, api.Status(http.StatusMethodNotAllowed, errors.New("method_not_allowed"))
+		}
+
+		var requestData struct {
+			Name     string `json:"name"`
+			Password string `json:"password"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
+			return nil, api.Status(http.StatusBadRequest, errors.New("invalid_json"))
+		}
+
+		var playerId int
+		var passwordHash string
+		err := database.QueryRow("SELECT id, password_hash FROM players WHERE name = ?", requestData.Name).Scan(&playerId, &passwordHash)
+		if err == sql.ErrNoRows {
+			return nil, api.Status(http.StatusUnauthorized, errors.New("invalid_credentials"))
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if err := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(requestData.Password)); err != nil {
+			return nil, api.Status(http.StatusUnauthorized, errors.New("invalid_credentials"))
+		}
+
+		token, err := newToken()
+		if err != nil {
+			return nil, err
+		}
+
+		expiresAt := time.Now().Add(sessionTTL)
+		if _, err := database.Exec("INSERT INTO sessions (token, player_id, expires_at) VALUES (?, ?, ?)", token, playerId, expiresAt); err != nil {
+			return nil, err
+		}
+
+		return struct {
+			Token     string `json:"token"`
+			ExpiresAt string `json:"expires_at"`
+			Message   string `json:"message"`
+			Success   bool   `json:"success"`
+		}{token, expiresAt.Format(time.RFC3339), "logged_in", true}, nil
+	}
+}
+
+func handleLogout(database *sql.DB) api.HandlerFunc {
+	return func(r *http.Request) (any, error) {
+		if r.Method != http.MethodPost {
+			return nil, api.Status(http.StatusMethodNotAllowed, errors.New("method_not_allowed"))
+		}
+
+		token, ok := bearerToken(r)
+		if !ok {
+			return nil, api.Status(http.StatusUnauthorized, errors.New("missing_token"))
+		}
+
+		if _, err := database.Exec("DELETE FROM sessions WHERE token = ?", token); err != nil {
+			return nil, err
+		}
+
+		return api.Response{Message: "logged_out", Success: true}, nil
+	}
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return "", false
+	}
+	return strings.TrimPrefix(header, "Bearer "), true
+}
+
+func newToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// isUniqueConstraintErr reports whether err is a SQLite UNIQUE constraint
+// violation, e.g. two players registering with the same name.
+func isUniqueConstraintErr(err error) bool {
+	sqliteErr, ok := err.(sqlite3.Error)
+	return ok && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+}
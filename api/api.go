@@ -0,0 +1,68 @@
+// Package api holds the small amount of plumbing shared by every
+// api/* handler package: a typed handler signature and a single place
+// that turns a Go error into a JSON HTTP response.
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+// Response is the generic envelope used for simple success/failure replies.
+type Response struct {
+	Message string `json:"message"`
+	Success bool   `json:"success"`
+}
+
+// HandlerFunc is a typed handler: it takes an already-parsed request and
+// returns either a result to encode as JSON or an error describing what
+// went wrong.
+type HandlerFunc func(r *http.Request) (any, error)
+
+// StatusError lets a HandlerFunc report a specific HTTP status (400, 404,
+// 405, ...) while still satisfying the plain `error` return type.
+type StatusError struct {
+	Status int
+	Err    error
+}
+
+func (e *StatusError) Error() string { return e.Err.Error() }
+func (e *StatusError) Unwrap() error { return e.Err }
+
+// Status wraps err so that Wrap responds with the given HTTP status
+// instead of defaulting to 500.
+func Status(status int, err error) error {
+	return &StatusError{Status: status, Err: err}
+}
+
+// Wrap adapts a HandlerFunc into a standard http.HandlerFunc, writing the
+// result as JSON on success (with the given status) or formatting the
+// error on failure. This is what replaces the copy-pasted
+// respondWithError(w, 500) blocks every handler used to have.
+func Wrap(status int, fn HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		result, err := fn(r)
+		if err != nil {
+			httpError(w, r, err)
+			return
+		}
+
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
+func httpError(w http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusInternalServerError
+	if se, ok := err.(*StatusError); ok {
+		status = se.Status
+	} else {
+		log.Printf("%s %s: %v", r.Method, r.URL, err)
+	}
+
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(Response{Message: err.Error(), Success: false})
+}
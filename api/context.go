@@ -0,0 +1,22 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+type contextKey string
+
+const playerIDKey contextKey = "player_id"
+
+// WithPlayerID returns a copy of r carrying the authenticated player's id,
+// set by an auth middleware after resolving a session token.
+func WithPlayerID(r *http.Request, playerId int) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), playerIDKey, playerId))
+}
+
+// PlayerIDFromContext returns the player id set by WithPlayerID, if any.
+func PlayerIDFromContext(r *http.Request) (int, bool) {
+	playerId, ok := r.Context().Value(playerIDKey).(int)
+	return playerId, ok
+}
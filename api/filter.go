@@ -0,0 +1,78 @@
+package api
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// FilterColumn turns a raw query-string value into a SQL WHERE fragment
+// (with its bound arguments), or an error if the value is malformed.
+type FilterColumn func(value string) (clause string, args []any, err error)
+
+// DefaultLimit and MaxLimit bound GET ?limit= on list endpoints.
+const (
+	DefaultLimit = 50
+	MaxLimit     = 200
+)
+
+// ParsePagination reads limit/offset from q, defaulting limit to
+// DefaultLimit and offset to 0. It returns an error if either is present
+// but not a valid, non-negative integer; a limit above MaxLimit is clamped
+// to MaxLimit.
+func ParsePagination(q url.Values) (limit, offset int, err error) {
+	limit = DefaultLimit
+	offset = 0
+
+	if v := q.Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 0 {
+			return 0, 0, errors.New("invalid_limit")
+		}
+		if limit > MaxLimit {
+			limit = MaxLimit
+		}
+	}
+
+	if v := q.Get("offset"); v != "" {
+		offset, err = strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return 0, 0, errors.New("invalid_offset")
+		}
+	}
+
+	return limit, offset, nil
+}
+
+// BuildWhere safely composes a SQL WHERE clause from q, rejecting any query
+// key that isn't in allowed. limit/offset are pagination params, not
+// filters, and are ignored here.
+func BuildWhere(allowed map[string]FilterColumn, q url.Values) (string, []any, error) {
+	var clauses []string
+	var args []any
+
+	for key, values := range q {
+		if key == "limit" || key == "offset" {
+			continue
+		}
+
+		column, ok := allowed[key]
+		if !ok {
+			return "", nil, errors.New("unknown_filter: " + key)
+		}
+
+		clause, clauseArgs, err := column(values[0])
+		if err != nil {
+			return "", nil, errors.New("invalid_filter_value: " + key)
+		}
+
+		clauses = append(clauses, clause)
+		args = append(args, clauseArgs...)
+	}
+
+	if len(clauses) == 0 {
+		return "", args, nil
+	}
+	return " WHERE " + strings.Join(clauses, " AND "), args, nil
+}
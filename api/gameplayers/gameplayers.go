@@ -0,0 +1,73 @@
+// Package gameplayers exposes the endpoint that adds a player to a game.
+package gameplayers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/pablobfonseca/canastracounter-go/api"
+	"github.com/pablobfonseca/canastracounter-go/api/accounts"
+	"github.com/pablobfonseca/canastracounter-go/api/games"
+)
+
+// Init registers the gameplayers package's routes on mux.
+func Init(mux *http.ServeMux, database *sql.DB) {
+	mux.HandleFunc("/games/players/add", api.Wrap(http.StatusCreated, accounts.Middleware(database)(handleCreate(database))))
+}
+
+func handleCreate(database *sql.DB) api.HandlerFunc {
+	return func(r *http.Request) (any, error) {
+		if r.Method != http.MethodPost {
+			return nil, api.Status(http.StatusMethodNotAllowed, errors.New("method_not_allowed"))
+		}
+
+		var requestData struct {
+			PlayerId int `json:"player_id"`
+			GameId   int `json:"game_id"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
+			return nil, api.Status(http.StatusBadRequest, errors.New("invalid_json"))
+		}
+
+		authPlayerId, ok := api.PlayerIDFromContext(r)
+		if !ok {
+			return nil, api.Status(http.StatusUnauthorized, errors.New("unauthenticated"))
+		}
+		if authPlayerId != requestData.PlayerId {
+			return nil, api.Status(http.StatusForbidden, errors.New("forbidden: cannot add another player to a game"))
+		}
+
+		mode, _, err := games.ModeForGame(database, requestData.GameId)
+		if err == sql.ErrNoRows {
+			return nil, api.Status(http.StatusNotFound, errors.New("game_not_found"))
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := database.Exec("INSERT INTO game_players (player_id, game_id, score) VALUES (?, ?, ?)", requestData.PlayerId, requestData.GameId, mode.StartingScore())
+		if err != nil {
+			return nil, err
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+
+		games.Hub.Broadcast(requestData.GameId, struct {
+			Type     string `json:"type"`
+			GameId   int    `json:"game_id"`
+			PlayerId int    `json:"player_id"`
+		}{"player_joined", requestData.GameId, requestData.PlayerId})
+
+		return struct {
+			ID      int64  `json:"id"`
+			Message string `json:"message"`
+			Success bool   `json:"success"`
+		}{id, "game_player_created", true}, nil
+	}
+}
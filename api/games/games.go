@@ -0,0 +1,549 @@
+// Package games exposes game creation, score updates, the live scoreboard
+// websocket and per-game stats.
+package games
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pablobfonseca/canastracounter-go/api"
+	"github.com/pablobfonseca/canastracounter-go/api/accounts"
+	"golang.org/x/net/websocket"
+)
+
+type Game struct {
+	ID        int    `json:"id"`
+	MaxPoints string `json:"max_points"`
+	Mode      string `json:"mode"`
+}
+
+type GamePlayer struct {
+	PlayerId int `json:"player_id"`
+	GameId   int `json:"game_id"`
+	Score    int `json:"score"`
+}
+
+type ScoreEvent struct {
+	PlayerId  int    `json:"player_id"`
+	Delta     int    `json:"delta"`
+	CreatedAt string `json:"created_at"`
+}
+
+type Stats struct {
+	GameId   int          `json:"game_id"`
+	Mode     string       `json:"mode"`
+	Totals   []GamePlayer `json:"totals"`
+	History  []ScoreEvent `json:"history"`
+	Winner   *int         `json:"winner"`
+	Duration float64      `json:"duration_seconds"`
+}
+
+// Hub is the package-level websocket hub; gameplayers broadcasts join
+// events through it, and this package broadcasts score updates.
+var Hub = NewHub()
+
+// Init registers the games package's routes on mux.
+func Init(mux *http.ServeMux, database *sql.DB) {
+	mux.HandleFunc("/games/new", api.Wrap(http.StatusCreated, handleCreate(database)))
+	mux.HandleFunc("/games", api.Wrap(http.StatusOK, handleList(database)))
+	mux.HandleFunc("/games/update-score", api.Wrap(http.StatusCreated, accounts.Middleware(database)(handleUpdateScore(database))))
+	mux.HandleFunc("/games/", api.Wrap(http.StatusOK, handleStats(database)))
+	mux.Handle("/games/subscribe", websocket.Handler(handleSubscribe))
+}
+
+func handleCreate(database *sql.DB) api.HandlerFunc {
+	return func(r *http.Request) (any, error) {
+		if r.Method != http.MethodPost {
+			return nil, api.Status(http.StatusMethodNotAllowed, errors.New("method_not_allowed"))
+		}
+
+		var requestData struct {
+			MaxPoints int    `json:"max_points"`
+			Mode      string `json:"mode"`
+			ModeConfig
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
+			return nil, api.Status(http.StatusBadRequest, errors.New("invalid_json"))
+		}
+
+		if _, err := NewMode(requestData.Mode, requestData.MaxPoints, requestData.ModeConfig); err != nil {
+			return nil, api.Status(http.StatusBadRequest, err)
+		}
+
+		config, err := json.Marshal(requestData.ModeConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		mode := requestData.Mode
+		if mode == "" {
+			mode = "canastra"
+		}
+
+		result, err := database.Exec("INSERT INTO games (max_points, mode, config) VALUES (?, ?, ?)", requestData.MaxPoints, mode, string(config))
+		if err != nil {
+			return nil, err
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+
+		return struct {
+			GameId  int64  `json:"id"`
+			Message string `json:"message"`
+			Success bool   `json:"success"`
+		}{id, "game_created", true}, nil
+	}
+}
+
+// gameFilters whitelists the columns GET /games can be filtered on, so an
+// unknown query key is a 400 instead of silently being ignored.
+var gameFilters = map[string]api.FilterColumn{
+	"won": func(value string) (string, []any, error) {
+		won, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", nil, err
+		}
+		if won {
+			return "winner_id IS NOT NULL", nil, nil
+		}
+		return "winner_id IS NULL", nil, nil
+	},
+	"max_points": func(value string) (string, []any, error) {
+		maxPoints, err := strconv.Atoi(value)
+		if err != nil {
+			return "", nil, err
+		}
+		return "max_points = ?", []any{maxPoints}, nil
+	},
+	"created_after": func(value string) (string, []any, error) {
+		createdAfter, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return "", nil, err
+		}
+		return "created_at > ?", []any{createdAfter}, nil
+	},
+	"player_id": func(value string) (string, []any, error) {
+		playerId, err := strconv.Atoi(value)
+		if err != nil {
+			return "", nil, err
+		}
+		return "id IN (SELECT game_id FROM game_players WHERE player_id = ?)", []any{playerId}, nil
+	},
+}
+
+// handleList serves GET /games, returning every game the caller's filters
+// and pagination match so a dashboard isn't stuck loading every row.
+func handleList(database *sql.DB) api.HandlerFunc {
+	return func(r *http.Request) (any, error) {
+		if r.Method != http.MethodGet {
+			return nil, api.Status(http.StatusMethodNotAllowed, errors.New("method_not_allowed"))
+		}
+
+		limit, offset, err := api.ParsePagination(r.URL.Query())
+		if err != nil {
+			return nil, api.Status(http.StatusBadRequest, err)
+		}
+
+		where, args, err := api.BuildWhere(gameFilters, r.URL.Query())
+		if err != nil {
+			return nil, api.Status(http.StatusBadRequest, err)
+		}
+
+		var total int
+		if err := database.QueryRow("SELECT COUNT(*) FROM games"+where, args...).Scan(&total); err != nil {
+			return nil, err
+		}
+
+		queryArgs := append(append([]any{}, args...), limit, offset)
+		rows, err := database.Query("SELECT id, max_points, mode FROM games"+where+" ORDER BY id LIMIT ? OFFSET ?", queryArgs...)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		gamesList := make([]Game, 0)
+		for rows.Next() {
+			var game Game
+			var maxPoints int
+			if err := rows.Scan(&game.ID, &maxPoints, &game.Mode); err != nil {
+				return nil, err
+			}
+			game.MaxPoints = strconv.Itoa(maxPoints)
+			gamesList = append(gamesList, game)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+
+		return struct {
+			Data   []Game `json:"data"`
+			Total  int    `json:"total"`
+			Limit  int    `json:"limit"`
+			Offset int    `json:"offset"`
+		}{gamesList, total, limit, offset}, nil
+	}
+}
+
+func handleUpdateScore(database *sql.DB) api.HandlerFunc {
+	return func(r *http.Request) (any, error) {
+		if r.Method != http.MethodPut {
+			return nil, api.Status(http.StatusMethodNotAllowed, errors.New("method_not_allowed"))
+		}
+
+		var requestData struct {
+			GameId   int    `json:"game_id"`
+			PlayerId int    `json:"player_id"`
+			Score    int    `json:"score"`
+			Event    string `json:"event"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
+			return nil, api.Status(http.StatusBadRequest, errors.New("invalid_json"))
+		}
+
+		authPlayerId, ok := api.PlayerIDFromContext(r)
+		if !ok {
+			return nil, api.Status(http.StatusUnauthorized, errors.New("unauthenticated"))
+		}
+		if authPlayerId != requestData.PlayerId {
+			return nil, api.Status(http.StatusForbidden, errors.New("forbidden: cannot update another player's score"))
+		}
+
+		mode, ended, err := ModeForGame(database, requestData.GameId)
+		if err == sql.ErrNoRows {
+			return nil, api.Status(http.StatusNotFound, errors.New("game_not_found"))
+		}
+		if err != nil {
+			return nil, err
+		}
+		if ended {
+			return nil, api.Status(http.StatusBadRequest, errors.New("game_already_ended"))
+		}
+
+		var currentScore int
+		err = database.QueryRow("SELECT score FROM game_players WHERE game_id = ? AND player_id = ?", requestData.GameId, requestData.PlayerId).Scan(&currentScore)
+		if err == sql.ErrNoRows {
+			return nil, api.Status(http.StatusForbidden, errors.New("player_not_in_game"))
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		delta := requestData.Score + mode.BonusPoints(requestData.Event)
+		if err := mode.ValidateScoreDelta(currentScore, delta); err != nil {
+			return nil, api.Status(http.StatusBadRequest, err)
+		}
+
+		tx, err := database.Begin()
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := tx.Exec("UPDATE game_players SET score = score + ? WHERE game_id = ? AND player_id = ?", delta, requestData.GameId, requestData.PlayerId)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		rowsAffected, err := res.RowsAffected()
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if rowsAffected == 0 {
+			tx.Rollback()
+			return nil, api.Status(http.StatusForbidden, errors.New("player_not_in_game"))
+		}
+
+		_, err = tx.Exec("INSERT INTO score_events (game_id, player_id, delta) VALUES (?, ?, ?)", requestData.GameId, requestData.PlayerId, delta)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		var newScore int
+		err = tx.QueryRow("SELECT score FROM game_players WHERE game_id = ? AND player_id = ?", requestData.GameId, requestData.PlayerId).Scan(&newScore)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		if err = tx.Commit(); err != nil {
+			return nil, err
+		}
+
+		totalScores, err := gamePlayerTotals(database, strconv.Itoa(requestData.GameId))
+		if err == nil {
+			Hub.Broadcast(requestData.GameId, struct {
+				Type        string       `json:"type"`
+				GameId      int          `json:"game_id"`
+				PlayerId    int          `json:"player_id"`
+				NewScore    int          `json:"new_score"`
+				TotalScores []GamePlayer `json:"total_scores"`
+			}{"score_update", requestData.GameId, requestData.PlayerId, newScore, totalScores})
+		}
+
+		if err := markWinnerIfGameOver(database, requestData.GameId, mode, totalScores); err != nil {
+			return nil, err
+		}
+
+		return struct {
+			NewScore int    `json:"new_score"`
+			Message  string `json:"message"`
+			Success  bool   `json:"success"`
+		}{newScore, "game_updated", true}, nil
+	}
+}
+
+// markWinnerIfGameOver consults the mode to see if the game has ended and,
+// if so, records the winner and the end time on the game row.
+func markWinnerIfGameOver(database *sql.DB, gameId int, mode GameMode, totals []GamePlayer) error {
+	scores := make(map[int]int, len(totals))
+	for _, gamePlayer := range totals {
+		scores[gamePlayer.PlayerId] = gamePlayer.Score
+	}
+
+	winner, done := mode.IsGameOver(scores)
+	if !done {
+		return nil
+	}
+
+	_, err := database.Exec("UPDATE games SET winner_id = ?, ended_at = CURRENT_TIMESTAMP WHERE id = ?", winner, gameId)
+	return err
+}
+
+// handleStats serves GET /games/{id}/stats; any other path under /games/
+// that isn't registered elsewhere falls through to a 404.
+func handleStats(database *sql.DB) api.HandlerFunc {
+	return func(r *http.Request) (any, error) {
+		if r.Method != http.MethodGet {
+			return nil, api.Status(http.StatusMethodNotAllowed, errors.New("method_not_allowed"))
+		}
+
+		path := strings.TrimPrefix(r.URL.Path, "/games/")
+		if !strings.HasSuffix(path, "/stats") {
+			return nil, api.Status(http.StatusNotFound, errors.New("not_found"))
+		}
+
+		gameId, err := strconv.Atoi(strings.TrimSuffix(path, "/stats"))
+		if err != nil {
+			return nil, api.Status(http.StatusBadRequest, errors.New("invalid_game_id"))
+		}
+
+		stats, err := gameStats(database, gameId)
+		if err == sql.ErrNoRows {
+			return nil, api.Status(http.StatusNotFound, errors.New("game_not_found"))
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return stats, nil
+	}
+}
+
+// handleSubscribe upgrades a request to a websocket and keeps it subscribed
+// to a single game's score updates until the connection drops. A ping is
+// sent periodically so dead sockets get cleaned out of the hub.
+func handleSubscribe(ws *websocket.Conn) {
+	gameId, err := strconv.Atoi(ws.Request().URL.Query().Get("game_id"))
+	if err != nil {
+		ws.Close()
+		return
+	}
+
+	Hub.Subscribe(gameId, ws)
+	defer func() {
+		Hub.Unsubscribe(gameId, ws)
+		ws.Close()
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			var msg string
+			if err := websocket.Message.Receive(ws, &msg); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := websocket.Message.Send(ws, "ping"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// ModeForGame loads a game's mode and config and builds its GameMode, also
+// reporting whether the game has already ended. It's shared by this
+// package's update-score handler and by gameplayers, which needs a
+// player's mode-appropriate starting score when they join.
+func ModeForGame(database *sql.DB, gameId int) (mode GameMode, ended bool, err error) {
+	var maxPoints int
+	var modeName, configJSON string
+	var endedAt sql.NullTime
+	err = database.QueryRow("SELECT max_points, mode, config, ended_at FROM games WHERE id = ?", gameId).
+		Scan(&maxPoints, &modeName, &configJSON, &endedAt)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var config ModeConfig
+	if err := json.Unmarshal([]byte(configJSON), &config); err != nil {
+		return nil, false, err
+	}
+
+	mode, err = NewMode(modeName, maxPoints, config)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return mode, endedAt.Valid, nil
+}
+
+func gamePlayerTotals(database *sql.DB, gameId string) ([]GamePlayer, error) {
+	rows, err := database.Query("SELECT game_id, player_id, score FROM game_players WHERE game_id = ?", gameId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	gamePlayers := make([]GamePlayer, 0)
+	for rows.Next() {
+		gamePlayer := new(GamePlayer)
+		if err := rows.Scan(&gamePlayer.GameId, &gamePlayer.PlayerId, &gamePlayer.Score); err != nil {
+			return nil, err
+		}
+		gamePlayers = append(gamePlayers, *gamePlayer)
+	}
+	return gamePlayers, rows.Err()
+}
+
+func gameStats(database *sql.DB, gameId int) (*Stats, error) {
+	var mode string
+	var createdAt time.Time
+	var winnerId sql.NullInt64
+	var endedAt sql.NullTime
+	err := database.QueryRow("SELECT mode, created_at, winner_id, ended_at FROM games WHERE id = ?", gameId).
+		Scan(&mode, &createdAt, &winnerId, &endedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := gamePlayerTotals(database, strconv.Itoa(gameId))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := database.Query("SELECT player_id, delta, created_at FROM score_events WHERE game_id = ? ORDER BY created_at", gameId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := make([]ScoreEvent, 0)
+	var lastEventAt time.Time
+	for rows.Next() {
+		event := new(ScoreEvent)
+		var eventCreatedAt time.Time
+		if err := rows.Scan(&event.PlayerId, &event.Delta, &eventCreatedAt); err != nil {
+			return nil, err
+		}
+		event.CreatedAt = eventCreatedAt.Format(time.RFC3339)
+		lastEventAt = eventCreatedAt
+		history = append(history, *event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var winner *int
+	if winnerId.Valid {
+		id := int(winnerId.Int64)
+		winner = &id
+	}
+
+	finishedAt := time.Now()
+	if endedAt.Valid {
+		finishedAt = endedAt.Time
+	} else if !lastEventAt.IsZero() {
+		finishedAt = lastEventAt
+	}
+
+	return &Stats{
+		GameId:   gameId,
+		Mode:     mode,
+		Totals:   totals,
+		History:  history,
+		Winner:   winner,
+		Duration: finishedAt.Sub(createdAt).Seconds(),
+	}, nil
+}
+
+// hub keeps track of the live subscribers for each game so score updates
+// can be fanned out to browsers/mobile clients without them having to poll
+// GET /games/{id}/stats.
+type hub struct {
+	mu          sync.RWMutex
+	subscribers map[int]map[*websocket.Conn]bool
+}
+
+func NewHub() *hub {
+	return &hub{subscribers: make(map[int]map[*websocket.Conn]bool)}
+}
+
+func (h *hub) Subscribe(gameId int, conn *websocket.Conn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.subscribers[gameId] == nil {
+		h.subscribers[gameId] = make(map[*websocket.Conn]bool)
+	}
+	h.subscribers[gameId][conn] = true
+}
+
+func (h *hub) Unsubscribe(gameId int, conn *websocket.Conn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.subscribers[gameId], conn)
+	if len(h.subscribers[gameId]) == 0 {
+		delete(h.subscribers, gameId)
+	}
+}
+
+func (h *hub) Broadcast(gameId int, message any) {
+	h.mu.RLock()
+	conns := make([]*websocket.Conn, 0, len(h.subscribers[gameId]))
+	for conn := range h.subscribers[gameId] {
+		conns = append(conns, conn)
+	}
+	h.mu.RUnlock()
+
+	for _, conn := range conns {
+		if err := websocket.JSON.Send(conn, message); err != nil {
+			h.Unsubscribe(gameId, conn)
+			conn.Close()
+		}
+	}
+}
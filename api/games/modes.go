@@ -0,0 +1,179 @@
+package games
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ModeConfig is the mode-specific portion of a game's settings, posted on
+// POST /games/new and persisted as the games.config JSON blob. Fields only
+// apply to the modes that use them.
+type ModeConfig struct {
+	TeamOf map[string]int `json:"team_of,omitempty"`
+}
+
+// GameMode encapsulates the scoring rules for a game: what deltas are
+// legal, when the game is over, and how much a named bonus event is worth.
+// Canastra-specific rules (canastra limpa/suja, penalties, team scoring)
+// live behind this interface so /games/update-score stays mode-agnostic.
+type GameMode interface {
+	ValidateScoreDelta(current, delta int) error
+	IsGameOver(scores map[int]int) (winner int, done bool)
+	BonusPoints(event string) int
+
+	// StartingScore is the score a player is seeded with when joining a
+	// game in this mode. Most modes start at zero, but a mode that counts
+	// down to elimination (deathmatch) needs a positive starting score so
+	// "hasn't scored yet" can't be mistaken for "eliminated".
+	StartingScore() int
+}
+
+// NewMode builds the GameMode for the given mode name and config.
+func NewMode(mode string, maxPoints int, config ModeConfig) (GameMode, error) {
+	switch mode {
+	case "", "canastra":
+		return &canastraMode{maxPoints: maxPoints}, nil
+	case "deathmatch":
+		return &deathmatchMode{maxPoints: maxPoints}, nil
+	case "teams":
+		teamOf := make(map[int]int, len(config.TeamOf))
+		for playerId, teamId := range config.TeamOf {
+			id, err := strconv.Atoi(playerId)
+			if err != nil {
+				return nil, fmt.Errorf("invalid team_of player id %q: %w", playerId, err)
+			}
+			teamOf[id] = teamId
+		}
+		return &teamsMode{maxPoints: maxPoints, teamOf: teamOf}, nil
+	default:
+		return nil, fmt.Errorf("unknown_mode: %s", mode)
+	}
+}
+
+// canastraMode is the classic canastra scoring: positive and negative
+// (penalty) deltas are both legal, and the first player to reach max
+// points wins.
+type canastraMode struct {
+	maxPoints int
+}
+
+func (m *canastraMode) ValidateScoreDelta(current, delta int) error {
+	if delta == 0 {
+		return errors.New("delta_cannot_be_zero")
+	}
+	if delta > m.maxPoints {
+		return fmt.Errorf("delta_too_large: %d exceeds max_points %d", delta, m.maxPoints)
+	}
+	if current+delta < -m.maxPoints {
+		return fmt.Errorf("delta_exceeds_penalty_floor: resulting score %d is below %d", current+delta, -m.maxPoints)
+	}
+	return nil
+}
+
+func (m *canastraMode) IsGameOver(scores map[int]int) (int, bool) {
+	for playerId, score := range scores {
+		if score >= m.maxPoints {
+			return playerId, true
+		}
+	}
+	return 0, false
+}
+
+func (m *canastraMode) BonusPoints(event string) int {
+	switch event {
+	case "canastra_limpa":
+		return 200
+	case "canastra_suja":
+		return 100
+	default:
+		return 0
+	}
+}
+
+func (m *canastraMode) StartingScore() int {
+	return 0
+}
+
+// deathmatchMode seeds every player at maxPoints and has them take damage
+// via negative deltas; the last player with a positive score wins.
+type deathmatchMode struct {
+	maxPoints int
+}
+
+func (m *deathmatchMode) ValidateScoreDelta(current, delta int) error {
+	if current <= 0 {
+		return errors.New("player_already_eliminated")
+	}
+	if delta >= 0 {
+		return errors.New("deathmatch_requires_a_negative_delta")
+	}
+	return nil
+}
+
+func (m *deathmatchMode) IsGameOver(scores map[int]int) (int, bool) {
+	if len(scores) < 2 {
+		return 0, false
+	}
+
+	var alive []int
+	for playerId, score := range scores {
+		if score > 0 {
+			alive = append(alive, playerId)
+		}
+	}
+	if len(alive) == 1 {
+		return alive[0], true
+	}
+	return 0, false
+}
+
+func (m *deathmatchMode) BonusPoints(event string) int {
+	return 0
+}
+
+func (m *deathmatchMode) StartingScore() int {
+	return m.maxPoints
+}
+
+// teamsMode sums player scores per team; the winner returned is the team
+// id, not a player id, once a team reaches max points.
+type teamsMode struct {
+	maxPoints int
+	teamOf    map[int]int
+}
+
+func (m *teamsMode) ValidateScoreDelta(current, delta int) error {
+	if delta == 0 {
+		return errors.New("delta_cannot_be_zero")
+	}
+	if delta > m.maxPoints {
+		return fmt.Errorf("delta_too_large: %d exceeds max_points %d", delta, m.maxPoints)
+	}
+	if current+delta < 0 {
+		return fmt.Errorf("delta_would_make_score_negative: %d", current+delta)
+	}
+	return nil
+}
+
+func (m *teamsMode) IsGameOver(scores map[int]int) (int, bool) {
+	teamTotals := make(map[int]int)
+	for playerId, score := range scores {
+		teamTotals[m.teamOf[playerId]] += score
+	}
+
+	for teamId, total := range teamTotals {
+		if total >= m.maxPoints {
+			return teamId, true
+		}
+	}
+	return 0, false
+}
+
+func (m *teamsMode) BonusPoints(event string) int {
+	return 0
+}
+
+func (m *teamsMode) StartingScore() int {
+	return 0
+}
@@ -0,0 +1,90 @@
+// Package players exposes the filterable, paginated player listing.
+// Registration lives in api/accounts now (POST /account/register).
+package players
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pablobfonseca/canastracounter-go/api"
+)
+
+type Player struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// Init registers the players package's routes on mux.
+func Init(mux *http.ServeMux, database *sql.DB) {
+	mux.HandleFunc("/players", api.Wrap(http.StatusOK, handleList(database)))
+}
+
+// playerFilters whitelists the columns GET /players can be filtered on.
+var playerFilters = map[string]api.FilterColumn{
+	"player_id": func(value string) (string, []any, error) {
+		playerId, err := strconv.Atoi(value)
+		if err != nil {
+			return "", nil, err
+		}
+		return "id = ?", []any{playerId}, nil
+	},
+	"created_after": func(value string) (string, []any, error) {
+		createdAfter, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return "", nil, err
+		}
+		return "created_at > ?", []any{createdAfter}, nil
+	},
+}
+
+func handleList(database *sql.DB) api.HandlerFunc {
+	return func(r *http.Request) (any, error) {
+		if r.Method != http.MethodGet {
+			return nil, api.Status(http.StatusMethodNotAllowed, errors.New("method_not_allowed"))
+		}
+
+		limit, offset, err := api.ParsePagination(r.URL.Query())
+		if err != nil {
+			return nil, api.Status(http.StatusBadRequest, err)
+		}
+
+		where, args, err := api.BuildWhere(playerFilters, r.URL.Query())
+		if err != nil {
+			return nil, api.Status(http.StatusBadRequest, err)
+		}
+
+		var total int
+		if err := database.QueryRow("SELECT COUNT(*) FROM players"+where, args...).Scan(&total); err != nil {
+			return nil, err
+		}
+
+		queryArgs := append(append([]any{}, args...), limit, offset)
+		rows, err := database.Query("SELECT id, name FROM players"+where+" ORDER BY id LIMIT ? OFFSET ?", queryArgs...)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		playersList := make([]Player, 0)
+		for rows.Next() {
+			var player Player
+			if err := rows.Scan(&player.ID, &player.Name); err != nil {
+				return nil, err
+			}
+			playersList = append(playersList, player)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+
+		return struct {
+			Data   []Player `json:"data"`
+			Total  int      `json:"total"`
+			Limit  int      `json:"limit"`
+			Offset int      `json:"offset"`
+		}{playersList, total, limit, offset}, nil
+	}
+}
@@ -0,0 +1,63 @@
+// Package db owns the SQLite connection and schema for canastracounter.
+package db
+
+import (
+	"database/sql"
+	"log"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS players (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL UNIQUE,
+	password_hash TEXT NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE IF NOT EXISTS games (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	max_points INTEGER NOT NULL,
+	mode TEXT NOT NULL DEFAULT 'canastra',
+	config TEXT NOT NULL DEFAULT '{}',
+	winner_id INTEGER,
+	ended_at DATETIME,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE IF NOT EXISTS game_players (
+	game_id INTEGER NOT NULL,
+	player_id INTEGER NOT NULL,
+	score INTEGER DEFAULT 0,
+	PRIMARY KEY (player_id, game_id)
+	FOREIGN KEY(player_id) REFERENCES players(id),
+	FOREIGN KEY(game_id) REFERENCES games(id)
+);
+CREATE TABLE IF NOT EXISTS score_events (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	game_id INTEGER NOT NULL,
+	player_id INTEGER NOT NULL,
+	delta INTEGER NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY(game_id) REFERENCES games(id),
+	FOREIGN KEY(player_id) REFERENCES players(id)
+);
+CREATE TABLE IF NOT EXISTS sessions (
+	token TEXT PRIMARY KEY,
+	player_id INTEGER NOT NULL,
+	expires_at DATETIME NOT NULL,
+	FOREIGN KEY(player_id) REFERENCES players(id)
+);`
+
+// Init opens the SQLite database at path and makes sure the schema exists.
+func Init(path string) *sql.DB {
+	database, err := sql.Open("sqlite3", path)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if _, err := database.Exec(schema); err != nil {
+		log.Fatal(err)
+	}
+
+	return database
+}